@@ -0,0 +1,328 @@
+package interp
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// Execute an eBPF instruction at program[s.PC].
+func (s *State) Execute(program []Instruction) error {
+	instr := program[s.PC]
+	switch instr.Opcode {
+	case ALU64_ADD_IMM:
+		s.Regs[instr.Dst] += int64(instr.Imm)
+	case ALU64_ADD_REG:
+		s.Regs[instr.Dst] += s.Regs[instr.Src]
+	case ALU64_SUB_IMM:
+		s.Regs[instr.Dst] -= int64(instr.Imm)
+	case ALU64_SUB_REG:
+		s.Regs[instr.Dst] -= s.Regs[instr.Src]
+	case ALU64_MUL_IMM:
+		s.Regs[instr.Dst] *= int64(instr.Imm)
+	case ALU64_MUL_REG:
+		s.Regs[instr.Dst] *= s.Regs[instr.Src]
+	case ALU64_DIV_IMM:
+		if instr.Imm == 0 {
+			return fmt.Errorf("%s: division by zero", mnemonic(instr.Opcode))
+		}
+		s.Regs[instr.Dst] /= int64(instr.Imm)
+	case ALU64_DIV_REG:
+		if s.Regs[instr.Src] == 0 {
+			return fmt.Errorf("%s: division by zero", mnemonic(instr.Opcode))
+		}
+		s.Regs[instr.Dst] /= s.Regs[instr.Src]
+	case ALU64_OR_IMM:
+		s.Regs[instr.Dst] |= int64(instr.Imm)
+	case ALU64_OR_REG:
+		s.Regs[instr.Dst] |= s.Regs[instr.Src]
+	case ALU64_AND_IMM:
+		s.Regs[instr.Dst] &= int64(instr.Imm)
+	case ALU64_AND_REG:
+		s.Regs[instr.Dst] &= s.Regs[instr.Src]
+	case ALU64_LSH_IMM:
+		s.Regs[instr.Dst] <<= uint64(instr.Imm)
+	case ALU64_LSH_REG:
+		s.Regs[instr.Dst] <<= uint64(s.Regs[instr.Src])
+	case ALU64_RSH_IMM:
+		s.Regs[instr.Dst] >>= uint64(instr.Imm)
+	case ALU64_RSH_REG:
+		s.Regs[instr.Dst] >>= uint64(s.Regs[instr.Src])
+	case ALU64_NEG:
+		s.Regs[instr.Dst] = -s.Regs[instr.Dst]
+	case ALU64_MOD_IMM:
+		if instr.Imm == 0 {
+			return fmt.Errorf("%s: division by zero", mnemonic(instr.Opcode))
+		}
+		s.Regs[instr.Dst] %= int64(instr.Imm)
+	case ALU64_MOD_REG:
+		if s.Regs[instr.Src] == 0 {
+			return fmt.Errorf("%s: division by zero", mnemonic(instr.Opcode))
+		}
+		s.Regs[instr.Dst] %= s.Regs[instr.Src]
+	case ALU64_XOR_IMM:
+		s.Regs[instr.Dst] ^= int64(instr.Imm)
+	case ALU64_XOR_REG:
+		s.Regs[instr.Dst] ^= s.Regs[instr.Src]
+	case ALU64_MOV_IMM:
+		s.Regs[instr.Dst] = int64(instr.Imm)
+	case ALU64_MOV_REG:
+		s.Regs[instr.Dst] = s.Regs[instr.Src]
+	case ALU64_ARSH_IMM:
+		// s.Regs is already int64, so >> is already an arithmetic
+		// (sign-preserving) shift.
+		s.Regs[instr.Dst] >>= uint64(instr.Imm)
+	case ALU64_ARSH_REG:
+		s.Regs[instr.Dst] >>= uint64(s.Regs[instr.Src])
+
+	// 32-bit ALU instructions: operate on the low 32 bits, zero-extend
+	// the result into the full register.
+	case ALU32_ADD_IMM:
+		s.setLow32(instr.Dst, int32(s.Regs[instr.Dst])+instr.Imm)
+	case ALU32_ADD_REG:
+		s.setLow32(instr.Dst, int32(s.Regs[instr.Dst])+int32(s.Regs[instr.Src]))
+	case ALU32_SUB_IMM:
+		s.setLow32(instr.Dst, int32(s.Regs[instr.Dst])-instr.Imm)
+	case ALU32_SUB_REG:
+		s.setLow32(instr.Dst, int32(s.Regs[instr.Dst])-int32(s.Regs[instr.Src]))
+	case ALU32_MUL_IMM:
+		s.setLow32(instr.Dst, int32(s.Regs[instr.Dst])*instr.Imm)
+	case ALU32_MUL_REG:
+		s.setLow32(instr.Dst, int32(s.Regs[instr.Dst])*int32(s.Regs[instr.Src]))
+	case ALU32_DIV_IMM:
+		if instr.Imm == 0 {
+			return fmt.Errorf("%s: division by zero", mnemonic(instr.Opcode))
+		}
+		s.setLow32(instr.Dst, int32(s.Regs[instr.Dst])/instr.Imm)
+	case ALU32_DIV_REG:
+		if int32(s.Regs[instr.Src]) == 0 {
+			return fmt.Errorf("%s: division by zero", mnemonic(instr.Opcode))
+		}
+		s.setLow32(instr.Dst, int32(s.Regs[instr.Dst])/int32(s.Regs[instr.Src]))
+	case ALU32_OR_IMM:
+		s.setLow32(instr.Dst, int32(s.Regs[instr.Dst])|instr.Imm)
+	case ALU32_OR_REG:
+		s.setLow32(instr.Dst, int32(s.Regs[instr.Dst])|int32(s.Regs[instr.Src]))
+	case ALU32_AND_IMM:
+		s.setLow32(instr.Dst, int32(s.Regs[instr.Dst])&instr.Imm)
+	case ALU32_AND_REG:
+		s.setLow32(instr.Dst, int32(s.Regs[instr.Dst])&int32(s.Regs[instr.Src]))
+	case ALU32_LSH_IMM:
+		s.setLow32(instr.Dst, int32(uint32(s.Regs[instr.Dst])<<uint32(instr.Imm)))
+	case ALU32_LSH_REG:
+		s.setLow32(instr.Dst, int32(uint32(s.Regs[instr.Dst])<<uint32(s.Regs[instr.Src])))
+	case ALU32_RSH_IMM:
+		s.setLow32(instr.Dst, int32(uint32(s.Regs[instr.Dst])>>uint32(instr.Imm)))
+	case ALU32_RSH_REG:
+		s.setLow32(instr.Dst, int32(uint32(s.Regs[instr.Dst])>>uint32(s.Regs[instr.Src])))
+	case ALU32_NEG:
+		s.setLow32(instr.Dst, -int32(s.Regs[instr.Dst]))
+	case ALU32_MOD_IMM:
+		if instr.Imm == 0 {
+			return fmt.Errorf("%s: division by zero", mnemonic(instr.Opcode))
+		}
+		s.setLow32(instr.Dst, int32(s.Regs[instr.Dst])%instr.Imm)
+	case ALU32_MOD_REG:
+		if int32(s.Regs[instr.Src]) == 0 {
+			return fmt.Errorf("%s: division by zero", mnemonic(instr.Opcode))
+		}
+		s.setLow32(instr.Dst, int32(s.Regs[instr.Dst])%int32(s.Regs[instr.Src]))
+	case ALU32_XOR_IMM:
+		s.setLow32(instr.Dst, int32(s.Regs[instr.Dst])^instr.Imm)
+	case ALU32_XOR_REG:
+		s.setLow32(instr.Dst, int32(s.Regs[instr.Dst])^int32(s.Regs[instr.Src]))
+	case ALU32_MOV_IMM:
+		s.setLow32(instr.Dst, instr.Imm)
+	case ALU32_MOV_REG:
+		s.setLow32(instr.Dst, int32(s.Regs[instr.Src]))
+	case ALU32_ARSH_IMM:
+		// int32 >> is arithmetic; setLow32 zero-extends the 32-bit result.
+		s.setLow32(instr.Dst, int32(s.Regs[instr.Dst])>>uint32(instr.Imm))
+	case ALU32_ARSH_REG:
+		s.setLow32(instr.Dst, int32(s.Regs[instr.Dst])>>uint32(s.Regs[instr.Src]))
+
+	case BYTESWAP_LE:
+		switch instr.Imm {
+		case 16:
+			s.Regs[instr.Dst] = int64(uint16(s.Regs[instr.Dst]))
+		case 32:
+			s.Regs[instr.Dst] = int64(uint32(s.Regs[instr.Dst]))
+		case 64:
+			// Already little-endian; nothing to do.
+		default:
+			return fmt.Errorf("BYTESWAP_LE: invalid width %d", instr.Imm)
+		}
+	case BYTESWAP_BE:
+		switch instr.Imm {
+		case 16:
+			s.Regs[instr.Dst] = int64(bits.ReverseBytes16(uint16(s.Regs[instr.Dst])))
+		case 32:
+			s.Regs[instr.Dst] = int64(bits.ReverseBytes32(uint32(s.Regs[instr.Dst])))
+		case 64:
+			s.Regs[instr.Dst] = int64(bits.ReverseBytes64(uint64(s.Regs[instr.Dst])))
+		default:
+			return fmt.Errorf("BYTESWAP_BE: invalid width %d", instr.Imm)
+		}
+
+		// Memory instructions
+	case MEM_LDDW:
+		// LDDW spans two instruction slots: the low 32 bits come from
+		// this instruction's Imm, the high 32 bits from the next slot's
+		// Imm (a pseudo-instruction Verify has already checked for).
+		s.Regs[instr.Dst] = int64(uint32(instr.Imm)) | int64(uint32(program[s.PC+1].Imm))<<32
+		s.PC++
+	case MEM_LDXW:
+		s.Regs[instr.Dst] = s.loadWord(s.Regs[instr.Src] + int64(instr.Offset))
+	case MEM_LDXH:
+		s.Regs[instr.Dst] = s.loadHalfWord(s.Regs[instr.Src] + int64(instr.Offset))
+	case MEM_LDXB:
+		s.Regs[instr.Dst] = s.loadByte(s.Regs[instr.Src] + int64(instr.Offset))
+	case MEM_LDXDW:
+		s.Regs[instr.Dst] = s.loadDoubleWord(s.Regs[instr.Src] + int64(instr.Offset))
+	case MEM_STW:
+		s.storeWord(s.Regs[instr.Dst]+int64(instr.Offset), instr.Imm)
+	case MEM_STH:
+		s.storeHalfWord(s.Regs[instr.Dst]+int64(instr.Offset), int16(instr.Imm))
+	case MEM_STB:
+		s.storeByte(s.Regs[instr.Dst]+int64(instr.Offset), int8(instr.Imm))
+	case MEM_STDW:
+		s.storeDoubleWord(s.Regs[instr.Dst]+int64(instr.Offset), int64(instr.Imm))
+	case MEM_STXW:
+		s.storeWord(s.Regs[instr.Dst]+int64(instr.Offset), int32(s.Regs[instr.Src]))
+	case MEM_STXH:
+		s.storeHalfWord(s.Regs[instr.Dst]+int64(instr.Offset), int16(s.Regs[instr.Src]))
+	case MEM_STXB:
+		s.storeByte(s.Regs[instr.Dst]+int64(instr.Offset), int8(s.Regs[instr.Src]))
+	case MEM_STXDW:
+		s.storeDoubleWord(s.Regs[instr.Dst]+int64(instr.Offset), s.Regs[instr.Src])
+
+	case BRANCH_JA:
+		s.PC += int(instr.Offset)
+	case BRANCH_JEQ_IMM:
+		if s.Regs[instr.Dst] == int64(instr.Imm) {
+			s.PC += int(instr.Offset)
+		}
+	case BRANCH_JEQ_REG:
+		if s.Regs[instr.Dst] == s.Regs[instr.Src] {
+			s.PC += int(instr.Offset)
+		}
+	case BRANCH_JGT_IMM:
+		// Unsigned comparison: JGT/JGE/JLT/JLE compare as uint64, unlike
+		// their JSGT/JSGE/JSLT/JSLE signed counterparts below.
+		if uint64(s.Regs[instr.Dst]) > uint64(instr.Imm) {
+			s.PC += int(instr.Offset)
+		}
+	case BRANCH_JGT_REG:
+		if uint64(s.Regs[instr.Dst]) > uint64(s.Regs[instr.Src]) {
+			s.PC += int(instr.Offset)
+		}
+	case BRANCH_JGE_IMM:
+		if uint64(s.Regs[instr.Dst]) >= uint64(instr.Imm) {
+			s.PC += int(instr.Offset)
+		}
+	case BRANCH_JGE_REG:
+		if uint64(s.Regs[instr.Dst]) >= uint64(s.Regs[instr.Src]) {
+			s.PC += int(instr.Offset)
+		}
+	case BRANCH_JLT_IMM:
+		if uint64(s.Regs[instr.Dst]) < uint64(instr.Imm) {
+			s.PC += int(instr.Offset)
+		}
+	case BRANCH_JLT_REG:
+		if uint64(s.Regs[instr.Dst]) < uint64(s.Regs[instr.Src]) {
+			s.PC += int(instr.Offset)
+		}
+	case BRANCH_JLE_IMM:
+		if uint64(s.Regs[instr.Dst]) <= uint64(instr.Imm) {
+			s.PC += int(instr.Offset)
+		}
+	case BRANCH_JLE_REG:
+		if uint64(s.Regs[instr.Dst]) <= uint64(s.Regs[instr.Src]) {
+			s.PC += int(instr.Offset)
+		}
+	case BRANCH_JSET_IMM:
+		if s.Regs[instr.Dst]&int64(instr.Imm) != 0 {
+			s.PC += int(instr.Offset)
+		}
+	case BRANCH_JSET_REG:
+		if s.Regs[instr.Dst]&s.Regs[instr.Src] != 0 {
+			s.PC += int(instr.Offset)
+		}
+	case BRANCH_JNE_IMM:
+		if s.Regs[instr.Dst] != int64(instr.Imm) {
+			s.PC += int(instr.Offset)
+		}
+	case BRANCH_JNE_REG:
+		if s.Regs[instr.Dst] != s.Regs[instr.Src] {
+			s.PC += int(instr.Offset)
+		}
+	case BRANCH_JSGT_IMM:
+		if s.Regs[instr.Dst] > int64(instr.Imm) {
+			s.PC += int(instr.Offset)
+		}
+	case BRANCH_JSGT_REG:
+		if s.Regs[instr.Dst] > s.Regs[instr.Src] {
+			s.PC += int(instr.Offset)
+		}
+	case BRANCH_JSGE_IMM:
+		if s.Regs[instr.Dst] >= int64(instr.Imm) {
+			s.PC += int(instr.Offset)
+		}
+	case BRANCH_JSGE_REG:
+		if s.Regs[instr.Dst] >= s.Regs[instr.Src] {
+			s.PC += int(instr.Offset)
+		}
+	case BRANCH_JSLT_IMM:
+		if s.Regs[instr.Dst] < int64(instr.Imm) {
+			s.PC += int(instr.Offset)
+		}
+	case BRANCH_JSLT_REG:
+		if s.Regs[instr.Dst] < s.Regs[instr.Src] {
+			s.PC += int(instr.Offset)
+		}
+	case BRANCH_JSLE_IMM:
+		if s.Regs[instr.Dst] <= int64(instr.Imm) {
+			s.PC += int(instr.Offset)
+		}
+	case BRANCH_JSLE_REG:
+		if s.Regs[instr.Dst] <= s.Regs[instr.Src] {
+			s.PC += int(instr.Offset)
+		}
+	case BRANCH_CALL:
+		if instr.Src == 0 {
+			// Helper call: Imm is the syscall id, args come from R1..R5,
+			// result goes back into R0.
+			fn, ok := s.Syscalls[uint32(instr.Imm)]
+			if !ok {
+				return fmt.Errorf("unknown syscall %d", uint32(instr.Imm))
+			}
+			ret, err := fn.Invoke(s, uint64(s.Regs[1]), uint64(s.Regs[2]), uint64(s.Regs[3]), uint64(s.Regs[4]), uint64(s.Regs[5]))
+			if err != nil {
+				return err
+			}
+			s.Regs[0] = int64(ret)
+		} else {
+			// Intra-program call: push a real frame so the callee gets
+			// its own R10 and the caller's R6-R9/R10 survive the call.
+			if s.CallStack.depth() >= MaxCallDepth {
+				return errors.New("call stack exceeded max depth")
+			}
+			newR10 := s.CallStack.push(s.PC+1, s.Regs)
+			s.Regs[10] = newR10
+			s.PC += int(instr.Offset)
+		}
+	case BRANCH_EXIT:
+		if frame, ok := s.CallStack.pop(); ok {
+			s.Regs[6], s.Regs[7], s.Regs[8], s.Regs[9] = frame.savedRegs[0], frame.savedRegs[1], frame.savedRegs[2], frame.savedRegs[3]
+			s.Regs[10] = frame.savedR10
+			s.PC = frame.returnPC - 1
+			return nil
+		}
+		return errors.New("exit")
+
+	default:
+		fmt.Printf("Unsupported opcode: %d\n", instr.Opcode)
+		return errors.New("unsupported opcode")
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package interp
+
+import "testing"
+
+// TestExecuteDivModByZero guards against a regression of the bug fixed
+// in 1ecda3e: a zero register operand to a div/mod instruction isn't
+// something Verify can catch statically, so Execute must reject it at
+// runtime instead of letting Go panic on integer divide by zero.
+func TestExecuteDivModByZero(t *testing.T) {
+	tests := []struct {
+		name    string
+		program []Instruction
+	}{
+		{
+			name: "alu64 div imm",
+			program: []Instruction{
+				{Opcode: ALU64_MOV_IMM, Dst: 0, Imm: 5},
+				{Opcode: ALU64_DIV_IMM, Dst: 0, Imm: 0},
+				{Opcode: BRANCH_EXIT},
+			},
+		},
+		{
+			name: "alu64 div reg",
+			program: []Instruction{
+				{Opcode: ALU64_MOV_IMM, Dst: 0, Imm: 5},
+				{Opcode: ALU64_MOV_IMM, Dst: 1, Imm: 0},
+				{Opcode: ALU64_DIV_REG, Dst: 0, Src: 1},
+				{Opcode: BRANCH_EXIT},
+			},
+		},
+		{
+			name: "alu64 mod imm",
+			program: []Instruction{
+				{Opcode: ALU64_MOV_IMM, Dst: 0, Imm: 5},
+				{Opcode: ALU64_MOD_IMM, Dst: 0, Imm: 0},
+				{Opcode: BRANCH_EXIT},
+			},
+		},
+		{
+			name: "alu64 mod reg",
+			program: []Instruction{
+				{Opcode: ALU64_MOV_IMM, Dst: 0, Imm: 5},
+				{Opcode: ALU64_MOV_IMM, Dst: 1, Imm: 0},
+				{Opcode: ALU64_MOD_REG, Dst: 0, Src: 1},
+				{Opcode: BRANCH_EXIT},
+			},
+		},
+		{
+			name: "alu32 div imm",
+			program: []Instruction{
+				{Opcode: ALU64_MOV_IMM, Dst: 0, Imm: 5},
+				{Opcode: ALU32_DIV_IMM, Dst: 0, Imm: 0},
+				{Opcode: BRANCH_EXIT},
+			},
+		},
+		{
+			name: "alu32 div reg",
+			program: []Instruction{
+				{Opcode: ALU64_MOV_IMM, Dst: 0, Imm: 5},
+				{Opcode: ALU64_MOV_IMM, Dst: 1, Imm: 0},
+				{Opcode: ALU32_DIV_REG, Dst: 0, Src: 1},
+				{Opcode: BRANCH_EXIT},
+			},
+		},
+		{
+			name: "alu32 mod imm",
+			program: []Instruction{
+				{Opcode: ALU64_MOV_IMM, Dst: 0, Imm: 5},
+				{Opcode: ALU32_MOD_IMM, Dst: 0, Imm: 0},
+				{Opcode: BRANCH_EXIT},
+			},
+		},
+		{
+			name: "alu32 mod reg",
+			program: []Instruction{
+				{Opcode: ALU64_MOV_IMM, Dst: 0, Imm: 5},
+				{Opcode: ALU64_MOV_IMM, Dst: 1, Imm: 0},
+				{Opcode: ALU32_MOD_REG, Dst: 0, Src: 1},
+				{Opcode: BRANCH_EXIT},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm, err := NewInterpreter(tt.program, VMOpts{})
+			if err != nil {
+				t.Fatalf("NewInterpreter: %v", err)
+			}
+			if err := vm.Run(); err == nil {
+				t.Fatal("Run: got nil error for division by zero, want an error")
+			}
+		})
+	}
+}
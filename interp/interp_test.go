@@ -0,0 +1,129 @@
+package interp
+
+import "testing"
+
+// run executes program to completion (or t.Fatal on an unexpected error)
+// and returns the final state, mirroring the ubpf/CertusOne conformance
+// test vectors: set up a tiny program, run it, check R0.
+func run(t *testing.T, program []Instruction) *State {
+	t.Helper()
+	if err := Verify(program); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	s := NewState()
+	for s.PC < len(program) {
+		if err := s.Execute(program); err != nil {
+			if err.Error() == "exit" {
+				break
+			}
+			t.Fatalf("Execute: %v", err)
+		}
+		s.PC++
+	}
+	return s
+}
+
+func TestConformance(t *testing.T) {
+	tests := []struct {
+		name    string
+		program []Instruction
+		wantR0  int64
+	}{
+		{
+			name: "alu64 mul reg",
+			program: []Instruction{
+				{Opcode: ALU64_MOV_IMM, Dst: 0, Imm: 7},
+				{Opcode: ALU64_MOV_IMM, Dst: 1, Imm: 6},
+				{Opcode: ALU64_MUL_REG, Dst: 0, Src: 1},
+				{Opcode: BRANCH_EXIT},
+			},
+			wantR0: 42,
+		},
+		{
+			name: "alu64 arsh imm is sign-preserving",
+			program: []Instruction{
+				{Opcode: ALU64_MOV_IMM, Dst: 0, Imm: -8},
+				{Opcode: ALU64_ARSH_IMM, Dst: 0, Imm: 1},
+				{Opcode: BRANCH_EXIT},
+			},
+			wantR0: -4,
+		},
+		{
+			name: "alu32 ops zero-extend",
+			program: []Instruction{
+				{Opcode: ALU64_MOV_IMM, Dst: 0, Imm: -1},
+				{Opcode: ALU32_ADD_IMM, Dst: 0, Imm: 1},
+				{Opcode: BRANCH_EXIT},
+			},
+			wantR0: 0,
+		},
+		{
+			name: "alu32 arsh operates on low 32 bits",
+			program: []Instruction{
+				{Opcode: ALU64_MOV_IMM, Dst: 0, Imm: -8},
+				{Opcode: ALU32_ARSH_IMM, Dst: 0, Imm: 1},
+				{Opcode: BRANCH_EXIT},
+			},
+			wantR0: 0xfffffffc,
+		},
+		{
+			name: "lddw combines both immediate slots",
+			program: []Instruction{
+				{Opcode: MEM_LDDW, Dst: 0, Imm: 1},
+				{Opcode: 0, Imm: 1}, // pseudo-instruction: high 32 bits
+				{Opcode: BRANCH_EXIT},
+			},
+			wantR0: 1<<32 | 1,
+		},
+		{
+			name: "byteswap be32",
+			program: []Instruction{
+				{Opcode: ALU64_MOV_IMM, Dst: 0, Imm: 0x01020304},
+				{Opcode: BYTESWAP_BE, Dst: 0, Imm: 32},
+				{Opcode: BRANCH_EXIT},
+			},
+			wantR0: 0x04030201,
+		},
+		{
+			name: "byteswap le64 is a no-op",
+			program: []Instruction{
+				{Opcode: ALU64_MOV_IMM, Dst: 0, Imm: 0x1234},
+				{Opcode: BYTESWAP_LE, Dst: 0, Imm: 64},
+				{Opcode: BRANCH_EXIT},
+			},
+			wantR0: 0x1234,
+		},
+		{
+			name: "jgt imm is unsigned",
+			program: []Instruction{
+				{Opcode: ALU64_MOV_IMM, Dst: 0, Imm: -1}, // R1 = all-ones, huge as unsigned
+				{Opcode: ALU64_MOV_REG, Dst: 1, Src: 0},
+				{Opcode: ALU64_MOV_IMM, Dst: 0, Imm: 0},
+				{Opcode: BRANCH_JGT_IMM, Dst: 1, Imm: 1, Offset: 1},
+				{Opcode: ALU64_MOV_IMM, Dst: 0, Imm: 7}, // skipped: -1 as uint64 > 1
+				{Opcode: BRANCH_EXIT},
+			},
+			wantR0: 0,
+		},
+		{
+			name: "jsgt imm is signed",
+			program: []Instruction{
+				{Opcode: ALU64_MOV_IMM, Dst: 1, Imm: -1},
+				{Opcode: ALU64_MOV_IMM, Dst: 0, Imm: 0},
+				{Opcode: BRANCH_JSGT_IMM, Dst: 1, Imm: 1, Offset: 1},
+				{Opcode: ALU64_MOV_IMM, Dst: 0, Imm: 7}, // not skipped: -1 < 1 signed
+				{Opcode: BRANCH_EXIT},
+			},
+			wantR0: 7,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := run(t, tt.program)
+			if s.Regs[0] != tt.wantR0 {
+				t.Errorf("R0 = %d (0x%x), want %d (0x%x)", s.Regs[0], s.Regs[0], tt.wantR0, tt.wantR0)
+			}
+		})
+	}
+}
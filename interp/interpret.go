@@ -0,0 +1,33 @@
+package interp
+
+import "fmt"
+
+// Interpret decodes, verifies and runs a raw eBPF bytecode buffer,
+// printing the final register file. It is a convenience wrapper around
+// Decode and NewInterpreter for simple, single-program use; callers that
+// need a loaded Program (e.g. from an ELF object) should use
+// NewInterpreterFromProgram instead.
+func Interpret(bytecode []byte) error {
+	program := Decode(bytecode)
+
+	vm, err := NewInterpreter(program, VMOpts{})
+	if err != nil {
+		return fmt.Errorf("verify: %w", err)
+	}
+
+	// Run returns the sentinel error "exit" for a top-level BRANCH_EXIT,
+	// the expected way a program finishes; any other error is a real
+	// runtime failure and must reach the caller.
+	runErr := vm.Run()
+	if runErr != nil && runErr.Error() != "exit" {
+		return fmt.Errorf("run: %w", runErr)
+	}
+
+	// print registers
+	fmt.Println("Registers:")
+	for i, reg := range vm.State.Regs {
+		fmt.Printf("R%d: %d\n", i, reg)
+	}
+
+	return nil
+}
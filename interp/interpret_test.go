@@ -0,0 +1,28 @@
+package interp
+
+import "testing"
+
+// TestInterpretReturnsNilOnNormalExit checks that Interpret's "exit" is
+// the expected top-level BRANCH_EXIT termination, not surfaced as an
+// error.
+func TestInterpretReturnsNilOnNormalExit(t *testing.T) {
+	bytecode := []byte{
+		BRANCH_EXIT, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}
+	if err := Interpret(bytecode); err != nil {
+		t.Fatalf("Interpret: %v, want nil", err)
+	}
+}
+
+// TestInterpretPropagatesRunError guards against a regression where
+// Interpret only printed a vm.Run() failure and still returned nil,
+// leaving callers with no way to detect a runtime error.
+func TestInterpretPropagatesRunError(t *testing.T) {
+	bytecode := []byte{
+		ALU64_DIV_IMM, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // r0 /= 0
+		BRANCH_EXIT, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}
+	if err := Interpret(bytecode); err == nil {
+		t.Fatal("Interpret: got nil error for a division by zero, want an error")
+	}
+}
@@ -0,0 +1,223 @@
+package interp
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrComputeExceeded is returned once a metered State's CUMax budget is
+// spent.
+var ErrComputeExceeded = errors.New("compute budget exceeded")
+
+// cuCallSurcharge is the extra compute-unit cost charged for a
+// BRANCH_CALL, on top of the flat per-instruction charge, reflecting the
+// added overhead of a syscall or intra-program call.
+const cuCallSurcharge = 10
+
+// chargeCU deducts cost from the compute-unit budget. It is a no-op when
+// CUMax is zero (metering disabled).
+func (s *State) chargeCU(cost int) error {
+	if s.CUMax == 0 {
+		return nil
+	}
+	s.CUMax -= cost
+	if s.CUMax <= 0 {
+		s.CUMax = 0
+		return ErrComputeExceeded
+	}
+	return nil
+}
+
+// TraceSink receives a formatted line before each instruction executes.
+// Implementations can write to a log, a ring buffer, testing.T, etc.
+type TraceSink interface {
+	Printf(format string, args ...any)
+}
+
+// VMOpts configures a NewInterpreter call.
+type VMOpts struct {
+	// CUMax is the initial compute-unit budget; zero disables metering.
+	CUMax int
+	// Trace, if set, is invoked before every instruction.
+	Trace TraceSink
+	// Syscalls, if set, replaces the interpreter's default (empty)
+	// syscall table.
+	Syscalls map[uint32]Syscall
+}
+
+// Interpreter runs a verified program against a State.
+type Interpreter struct {
+	State   *State
+	program []Instruction
+}
+
+// NewInterpreter verifies program and returns an Interpreter ready to
+// Run it.
+func NewInterpreter(program []Instruction, opts VMOpts) (*Interpreter, error) {
+	if err := Verify(program); err != nil {
+		return nil, err
+	}
+	return newInterpreter(program, NewState(), opts), nil
+}
+
+// NewInterpreterFromProgram verifies p.Text and returns an Interpreter
+// ready to Run it, with p.RO mapped into the initial State's Memory and
+// p.Funcs available for BPF-to-BPF calls resolved by a loader.
+func NewInterpreterFromProgram(p *Program, opts VMOpts) (*Interpreter, error) {
+	if err := Verify(p.Text); err != nil {
+		return nil, err
+	}
+
+	if len(p.RO) > MemorySize {
+		return nil, fmt.Errorf("program RO data (%d bytes) exceeds memory size (%d bytes)", len(p.RO), MemorySize)
+	}
+
+	s := NewState()
+	copy(s.Memory, p.RO)
+	s.PC = p.Entrypoint
+
+	return newInterpreter(p.Text, s, opts), nil
+}
+
+// newInterpreter applies opts to s and pairs it with program.
+func newInterpreter(program []Instruction, s *State, opts VMOpts) *Interpreter {
+	s.CUMax = opts.CUMax
+	s.Trace = opts.Trace
+	if opts.Syscalls != nil {
+		s.Syscalls = opts.Syscalls
+	}
+
+	return &Interpreter{State: s, program: program}
+}
+
+// Run executes the program until it exits, hits an error, or runs out of
+// compute units.
+func (vm *Interpreter) Run() error {
+	s := vm.State
+	for s.PC < len(vm.program) {
+		instr := vm.program[s.PC]
+
+		if s.Trace != nil {
+			s.Trace.Printf("pc=%d op=0x%02x %s regs=%v", s.PC, instr.Opcode, mnemonic(instr.Opcode), s.Regs)
+		}
+
+		cost := 1
+		if instr.Opcode == BRANCH_CALL {
+			cost += cuCallSurcharge
+		}
+		if err := s.chargeCU(cost); err != nil {
+			return err
+		}
+
+		if err := s.Execute(vm.program); err != nil {
+			return err
+		}
+		s.PC++
+	}
+	return nil
+}
+
+// mnemonic returns the human-readable name of an opcode, for tracing.
+func mnemonic(op uint8) string {
+	if name, ok := opcodeNames[op]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(0x%02x)", op)
+}
+
+var opcodeNames = map[uint8]string{
+	ALU64_ADD_IMM:  "ALU64_ADD_IMM",
+	ALU64_ADD_REG:  "ALU64_ADD_REG",
+	ALU64_SUB_IMM:  "ALU64_SUB_IMM",
+	ALU64_SUB_REG:  "ALU64_SUB_REG",
+	ALU64_MUL_IMM:  "ALU64_MUL_IMM",
+	ALU64_MUL_REG:  "ALU64_MUL_REG",
+	ALU64_DIV_IMM:  "ALU64_DIV_IMM",
+	ALU64_DIV_REG:  "ALU64_DIV_REG",
+	ALU64_OR_IMM:   "ALU64_OR_IMM",
+	ALU64_OR_REG:   "ALU64_OR_REG",
+	ALU64_AND_IMM:  "ALU64_AND_IMM",
+	ALU64_AND_REG:  "ALU64_AND_REG",
+	ALU64_LSH_IMM:  "ALU64_LSH_IMM",
+	ALU64_LSH_REG:  "ALU64_LSH_REG",
+	ALU64_RSH_IMM:  "ALU64_RSH_IMM",
+	ALU64_RSH_REG:  "ALU64_RSH_REG",
+	ALU64_NEG:      "ALU64_NEG",
+	ALU64_MOD_IMM:  "ALU64_MOD_IMM",
+	ALU64_MOD_REG:  "ALU64_MOD_REG",
+	ALU64_XOR_IMM:  "ALU64_XOR_IMM",
+	ALU64_XOR_REG:  "ALU64_XOR_REG",
+	ALU64_MOV_IMM:  "ALU64_MOV_IMM",
+	ALU64_MOV_REG:  "ALU64_MOV_REG",
+	ALU64_ARSH_IMM: "ALU64_ARSH_IMM",
+	ALU64_ARSH_REG: "ALU64_ARSH_REG",
+
+	ALU32_ADD_IMM:  "ALU32_ADD_IMM",
+	ALU32_ADD_REG:  "ALU32_ADD_REG",
+	ALU32_SUB_IMM:  "ALU32_SUB_IMM",
+	ALU32_SUB_REG:  "ALU32_SUB_REG",
+	ALU32_MUL_IMM:  "ALU32_MUL_IMM",
+	ALU32_MUL_REG:  "ALU32_MUL_REG",
+	ALU32_DIV_IMM:  "ALU32_DIV_IMM",
+	ALU32_DIV_REG:  "ALU32_DIV_REG",
+	ALU32_OR_IMM:   "ALU32_OR_IMM",
+	ALU32_OR_REG:   "ALU32_OR_REG",
+	ALU32_AND_IMM:  "ALU32_AND_IMM",
+	ALU32_AND_REG:  "ALU32_AND_REG",
+	ALU32_LSH_IMM:  "ALU32_LSH_IMM",
+	ALU32_LSH_REG:  "ALU32_LSH_REG",
+	ALU32_RSH_IMM:  "ALU32_RSH_IMM",
+	ALU32_RSH_REG:  "ALU32_RSH_REG",
+	ALU32_NEG:      "ALU32_NEG",
+	ALU32_MOD_IMM:  "ALU32_MOD_IMM",
+	ALU32_MOD_REG:  "ALU32_MOD_REG",
+	ALU32_XOR_IMM:  "ALU32_XOR_IMM",
+	ALU32_XOR_REG:  "ALU32_XOR_REG",
+	ALU32_MOV_IMM:  "ALU32_MOV_IMM",
+	ALU32_MOV_REG:  "ALU32_MOV_REG",
+	ALU32_ARSH_IMM: "ALU32_ARSH_IMM",
+	ALU32_ARSH_REG: "ALU32_ARSH_REG",
+
+	BYTESWAP_LE: "BYTESWAP_LE",
+	BYTESWAP_BE: "BYTESWAP_BE",
+
+	MEM_LDDW:  "MEM_LDDW",
+	MEM_LDXW:  "MEM_LDXW",
+	MEM_LDXH:  "MEM_LDXH",
+	MEM_LDXB:  "MEM_LDXB",
+	MEM_LDXDW: "MEM_LDXDW",
+	MEM_STW:   "MEM_STW",
+	MEM_STH:   "MEM_STH",
+	MEM_STB:   "MEM_STB",
+	MEM_STDW:  "MEM_STDW",
+	MEM_STXW:  "MEM_STXW",
+	MEM_STXH:  "MEM_STXH",
+	MEM_STXB:  "MEM_STXB",
+	MEM_STXDW: "MEM_STXDW",
+
+	BRANCH_JA:       "BRANCH_JA",
+	BRANCH_JEQ_IMM:  "BRANCH_JEQ_IMM",
+	BRANCH_JEQ_REG:  "BRANCH_JEQ_REG",
+	BRANCH_JGT_IMM:  "BRANCH_JGT_IMM",
+	BRANCH_JGT_REG:  "BRANCH_JGT_REG",
+	BRANCH_JGE_IMM:  "BRANCH_JGE_IMM",
+	BRANCH_JGE_REG:  "BRANCH_JGE_REG",
+	BRANCH_JLT_IMM:  "BRANCH_JLT_IMM",
+	BRANCH_JLT_REG:  "BRANCH_JLT_REG",
+	BRANCH_JLE_IMM:  "BRANCH_JLE_IMM",
+	BRANCH_JLE_REG:  "BRANCH_JLE_REG",
+	BRANCH_JSET_IMM: "BRANCH_JSET_IMM",
+	BRANCH_JSET_REG: "BRANCH_JSET_REG",
+	BRANCH_JNE_IMM:  "BRANCH_JNE_IMM",
+	BRANCH_JNE_REG:  "BRANCH_JNE_REG",
+	BRANCH_JSGT_IMM: "BRANCH_JSGT_IMM",
+	BRANCH_JSGT_REG: "BRANCH_JSGT_REG",
+	BRANCH_JSGE_IMM: "BRANCH_JSGE_IMM",
+	BRANCH_JSGE_REG: "BRANCH_JSGE_REG",
+	BRANCH_JSLT_IMM: "BRANCH_JSLT_IMM",
+	BRANCH_JSLT_REG: "BRANCH_JSLT_REG",
+	BRANCH_JSLE_IMM: "BRANCH_JSLE_IMM",
+	BRANCH_JSLE_REG: "BRANCH_JSLE_REG",
+	BRANCH_CALL:     "BRANCH_CALL",
+	BRANCH_EXIT:     "BRANCH_EXIT",
+}
@@ -0,0 +1,51 @@
+package interp
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type recordingTrace struct {
+	lines []string
+}
+
+func (r *recordingTrace) Printf(format string, args ...any) {
+	r.lines = append(r.lines, fmt.Sprintf(format, args...))
+}
+
+func TestInterpreterComputeBudgetExceeded(t *testing.T) {
+	program := []Instruction{
+		{Opcode: BRANCH_JA, Offset: -1}, // spin forever
+	}
+
+	vm, err := NewInterpreter(program, VMOpts{CUMax: 3})
+	if err != nil {
+		t.Fatalf("NewInterpreter: %v", err)
+	}
+
+	err = vm.Run()
+	if !errors.Is(err, ErrComputeExceeded) {
+		t.Fatalf("Run() = %v, want ErrComputeExceeded", err)
+	}
+}
+
+func TestInterpreterTrace(t *testing.T) {
+	program := []Instruction{
+		{Opcode: ALU64_MOV_IMM, Dst: 0, Imm: 1},
+		{Opcode: BRANCH_EXIT},
+	}
+
+	trace := &recordingTrace{}
+	vm, err := NewInterpreter(program, VMOpts{Trace: trace})
+	if err != nil {
+		t.Fatalf("NewInterpreter: %v", err)
+	}
+	if err := vm.Run(); err != nil && err.Error() != "exit" {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if len(trace.lines) != len(program) {
+		t.Fatalf("got %d trace lines, want %d", len(trace.lines), len(program))
+	}
+}
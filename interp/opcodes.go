@@ -0,0 +1,157 @@
+// Package interp implements an eBPF/SBF-style bytecode interpreter:
+// decoding, a static verifier, and an executor with syscalls, a call
+// stack, and compute-unit metering.
+package interp
+
+// Opcodes for 64-bit ALU instructions
+const (
+	ALU64_ADD_IMM  = 0x07
+	ALU64_ADD_REG  = 0x0f
+	ALU64_SUB_IMM  = 0x17
+	ALU64_SUB_REG  = 0x1f
+	ALU64_MUL_IMM  = 0x27
+	ALU64_MUL_REG  = 0x2f
+	ALU64_DIV_IMM  = 0x37
+	ALU64_DIV_REG  = 0x3f
+	ALU64_OR_IMM   = 0x47
+	ALU64_OR_REG   = 0x4f
+	ALU64_AND_IMM  = 0x57
+	ALU64_AND_REG  = 0x5f
+	ALU64_LSH_IMM  = 0x67
+	ALU64_LSH_REG  = 0x6f
+	ALU64_RSH_IMM  = 0x77
+	ALU64_RSH_REG  = 0x7f
+	ALU64_NEG      = 0x87
+	ALU64_MOD_IMM  = 0x97
+	ALU64_MOD_REG  = 0x9f
+	ALU64_XOR_IMM  = 0xa7
+	ALU64_XOR_REG  = 0xaf
+	ALU64_MOV_IMM  = 0xb7
+	ALU64_MOV_REG  = 0xbf
+	ALU64_ARSH_IMM = 0xc7
+	ALU64_ARSH_REG = 0xcf
+)
+
+// Opcodes for 32-bit ALU instructions. These operate on the low 32 bits
+// of Dst/Src and zero-extend the result into the full 64-bit register.
+const (
+	ALU32_ADD_IMM  = 0x04
+	ALU32_ADD_REG  = 0x0c
+	ALU32_SUB_IMM  = 0x14
+	ALU32_SUB_REG  = 0x1c
+	ALU32_MUL_IMM  = 0x24
+	ALU32_MUL_REG  = 0x2c
+	ALU32_DIV_IMM  = 0x34
+	ALU32_DIV_REG  = 0x3c
+	ALU32_OR_IMM   = 0x44
+	ALU32_OR_REG   = 0x4c
+	ALU32_AND_IMM  = 0x54
+	ALU32_AND_REG  = 0x5c
+	ALU32_LSH_IMM  = 0x64
+	ALU32_LSH_REG  = 0x6c
+	ALU32_RSH_IMM  = 0x74
+	ALU32_RSH_REG  = 0x7c
+	ALU32_NEG      = 0x84
+	ALU32_MOD_IMM  = 0x94
+	ALU32_MOD_REG  = 0x9c
+	ALU32_XOR_IMM  = 0xa4
+	ALU32_XOR_REG  = 0xac
+	ALU32_MOV_IMM  = 0xb4
+	ALU32_MOV_REG  = 0xbc
+	ALU32_ARSH_IMM = 0xc4
+	ALU32_ARSH_REG = 0xcc
+)
+
+// Opcodes for Byteswap instructions. Imm (16, 32 or 64) picks the width;
+// LE and BE each have a single opcode, not one per width.
+const (
+	BYTESWAP_LE = 0xd4
+	BYTESWAP_BE = 0xdc
+)
+
+// Opcodes for Memory instructions
+const (
+	MEM_LDDW    = 0x18
+	MEM_LDABSW  = 0x20
+	MEM_LDABSH  = 0x28
+	MEM_LDABSB  = 0x30
+	MEM_LDABSDW = 0x38
+	MEM_LDINDW  = 0x40
+	MEM_LDINDH  = 0x48
+	MEM_LDINDB  = 0x50
+	MEM_LDINDDW = 0x58
+	MEM_LDXW    = 0x61
+	MEM_LDXH    = 0x69
+	MEM_LDXB    = 0x71
+	MEM_LDXDW   = 0x79
+	MEM_STW     = 0x62
+	MEM_STH     = 0x6a
+	MEM_STB     = 0x72
+	MEM_STDW    = 0x7a
+	MEM_STXW    = 0x63
+	MEM_STXH    = 0x6b
+	MEM_STXB    = 0x73
+	MEM_STXDW   = 0x7b
+)
+
+// Opcodes for Branch instructions
+const (
+	BRANCH_JA       = 0x05
+	BRANCH_JEQ_IMM  = 0x15
+	BRANCH_JEQ_REG  = 0x1d
+	BRANCH_JGT_IMM  = 0x25
+	BRANCH_JGT_REG  = 0x2d
+	BRANCH_JGE_IMM  = 0x35
+	BRANCH_JGE_REG  = 0x3d
+	BRANCH_JLT_IMM  = 0xa5
+	BRANCH_JLT_REG  = 0xad
+	BRANCH_JLE_IMM  = 0xb5
+	BRANCH_JLE_REG  = 0xbd
+	BRANCH_JSET_IMM = 0x45
+	BRANCH_JSET_REG = 0x4d
+	BRANCH_JNE_IMM  = 0x55
+	BRANCH_JNE_REG  = 0x5d
+	BRANCH_JSGT_IMM = 0x65
+	BRANCH_JSGT_REG = 0x6d
+	BRANCH_JSGE_IMM = 0x75
+	BRANCH_JSGE_REG = 0x7d
+	BRANCH_JSLT_IMM = 0xc5
+	BRANCH_JSLT_REG = 0xcd
+	BRANCH_JSLE_IMM = 0xd5
+	BRANCH_JSLE_REG = 0xdd
+	BRANCH_CALL     = 0x85
+	BRANCH_EXIT     = 0x95
+)
+
+// eBPF Instruction format
+// +----------------+----------------+----------------+---------------+
+// | 8-bit Opcode   | 4-bit  Dest    | 4-bit  Src     | 16-bit Offset |
+// +----------------+----------------+----------------+---------------+
+// | 32-bit Immediate Value                                           |
+// +------------------------------------------------------------------+
+
+// eBPF Instruction
+type Instruction struct {
+	Opcode uint8
+	Dst    uint8
+	Src    uint8
+	Offset int16
+	Imm    int32
+}
+
+// Decode unpacks raw eBPF bytecode (8 bytes per slot) into Instructions.
+// Multi-slot instructions (MEM_LDDW) are decoded as two slots; Execute
+// consumes the second slot itself.
+func Decode(bytecode []byte) []Instruction {
+	program := make([]Instruction, len(bytecode)/8)
+	for i := 0; i < len(bytecode); i += 8 {
+		program[i/8] = Instruction{
+			Opcode: bytecode[i],
+			Dst:    bytecode[i+1] & 0x0F,
+			Src:    (bytecode[i+1] >> 4) & 0x0F,
+			Offset: int16(bytecode[i+2]) | int16(bytecode[i+3])<<8,
+			Imm:    int32(bytecode[i+4]) | int32(bytecode[i+5])<<8 | int32(bytecode[i+6])<<16 | int32(bytecode[i+7])<<24,
+		}
+	}
+	return program
+}
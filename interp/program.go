@@ -0,0 +1,17 @@
+package interp
+
+// Program is a fully-decoded, loaded eBPF program, as produced by a
+// loader (e.g. from an ELF object) and consumed by
+// NewInterpreterFromProgram.
+type Program struct {
+	// Text is the decoded instruction stream.
+	Text []Instruction
+	// RO holds the initial contents of read-only data (.rodata/.data),
+	// copied into the low end of the interpreter's Memory before Run.
+	RO []byte
+	// Entrypoint is the index into Text where execution begins.
+	Entrypoint int
+	// Funcs maps a loader-assigned function id to its entry index in
+	// Text, for BPF-to-BPF calls resolved from relocations.
+	Funcs map[uint32]int
+}
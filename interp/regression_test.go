@@ -0,0 +1,18 @@
+package interp
+
+import "testing"
+
+// TestVerifyRejectsOutOfRangeBranchCallTarget guards against a
+// regression of the bug fixed in 5840382: Verify used to special-case
+// BRANCH_CALL out of its branch-target bounds check, so an
+// intra-program call whose Offset sent PC out of range passed
+// verification and then panicked in Run.
+func TestVerifyRejectsOutOfRangeBranchCallTarget(t *testing.T) {
+	program := []Instruction{
+		{Opcode: BRANCH_CALL, Src: 1, Offset: -10},
+		{Opcode: BRANCH_EXIT},
+	}
+	if err := Verify(program); err == nil {
+		t.Fatal("Verify: got nil error for an out-of-range BRANCH_CALL target, want an error")
+	}
+}
@@ -0,0 +1,60 @@
+package interp
+
+// StackFrameSize is the scratch space reserved for each call frame,
+// matching the 512-byte stack frame real eBPF/SBF programs are compiled
+// against.
+const StackFrameSize = 512
+
+// MaxCallDepth bounds how many nested BRANCH_CALLs the interpreter will
+// follow before refusing to push another frame, mirroring the depth real
+// eBPF verifiers enforce.
+const MaxCallDepth = 8
+
+// StackSize is the total region reserved for the call stack, carved out
+// of the top of State.Memory so stack-relative MEM_LDX*/MEM_STX* through
+// R10-N resolve through the normal, bounds-checked load/store helpers.
+const StackSize = StackFrameSize * MaxCallDepth
+
+// callFrame is what BRANCH_CALL pushes and BRANCH_EXIT pops: the
+// instruction to resume at, and the callee-saved registers R6-R9 and R10.
+type callFrame struct {
+	returnPC  int
+	savedRegs [4]int64 // R6, R7, R8, R9
+	savedR10  int64
+}
+
+// Stack is the interpreter's call-frame stack.
+type Stack struct {
+	frames []callFrame
+}
+
+func NewStack() *Stack {
+	return &Stack{}
+}
+
+func (st *Stack) depth() int {
+	return len(st.frames)
+}
+
+// push saves the caller's frame (to be restored by pop) and returns the
+// new frame pointer: the stack grows down, so it sits StackFrameSize
+// below the caller's R10.
+func (st *Stack) push(returnPC int, regs [11]int64) int64 {
+	st.frames = append(st.frames, callFrame{
+		returnPC:  returnPC,
+		savedRegs: [4]int64{regs[6], regs[7], regs[8], regs[9]},
+		savedR10:  regs[10],
+	})
+	return regs[10] - StackFrameSize
+}
+
+// pop restores the caller's frame. ok is false if the call stack is
+// already empty, i.e. the caller is the outermost frame.
+func (st *Stack) pop() (frame callFrame, ok bool) {
+	if len(st.frames) == 0 {
+		return callFrame{}, false
+	}
+	frame = st.frames[len(st.frames)-1]
+	st.frames = st.frames[:len(st.frames)-1]
+	return frame, true
+}
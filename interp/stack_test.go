@@ -0,0 +1,68 @@
+package interp
+
+import "testing"
+
+// TestNewStateInitialR10 checks that a fresh State's frame pointer starts
+// at the top of Memory, the highest StackFrameSize bytes of which are
+// frame 0's scratch space.
+func TestNewStateInitialR10(t *testing.T) {
+	s := NewState()
+	if s.Regs[10] != int64(MemorySize) {
+		t.Errorf("R10 = %d, want %d", s.Regs[10], int64(MemorySize))
+	}
+}
+
+// TestBranchCallRestoresCalleeSavedRegisters checks that BRANCH_EXIT
+// restores R6-R9 and R10 from the frame BRANCH_CALL pushed, even though
+// the callee clobbered them.
+func TestBranchCallRestoresCalleeSavedRegisters(t *testing.T) {
+	program := []Instruction{
+		{Opcode: ALU64_MOV_IMM, Dst: 6, Imm: 111},
+		{Opcode: ALU64_MOV_IMM, Dst: 7, Imm: 222},
+		{Opcode: ALU64_MOV_IMM, Dst: 8, Imm: 333},
+		{Opcode: ALU64_MOV_IMM, Dst: 9, Imm: 444},
+		{Opcode: BRANCH_CALL, Src: 1, Offset: 1}, // call the callee at index 6
+		{Opcode: BRANCH_EXIT},                    // resumed here after the callee returns
+		{Opcode: ALU64_MOV_IMM, Dst: 6, Imm: 1},  // callee: clobber R6-R9
+		{Opcode: ALU64_MOV_IMM, Dst: 7, Imm: 2},
+		{Opcode: ALU64_MOV_IMM, Dst: 8, Imm: 3},
+		{Opcode: ALU64_MOV_IMM, Dst: 9, Imm: 4},
+		{Opcode: BRANCH_EXIT},
+	}
+
+	vm, err := NewInterpreter(program, VMOpts{})
+	if err != nil {
+		t.Fatalf("NewInterpreter: %v", err)
+	}
+	if err := vm.Run(); err != nil && err.Error() != "exit" {
+		t.Fatalf("Run: %v", err)
+	}
+
+	want := [4]int64{111, 222, 333, 444}
+	for i, r := range [4]uint8{6, 7, 8, 9} {
+		if vm.State.Regs[r] != want[i] {
+			t.Errorf("R%d = %d, want %d", r, vm.State.Regs[r], want[i])
+		}
+	}
+	if vm.State.Regs[10] != int64(MemorySize) {
+		t.Errorf("R10 = %d, want %d", vm.State.Regs[10], int64(MemorySize))
+	}
+}
+
+// TestBranchCallMaxCallDepth checks that an intra-program call nesting
+// past MaxCallDepth is rejected rather than growing the call stack
+// without bound.
+func TestBranchCallMaxCallDepth(t *testing.T) {
+	program := []Instruction{
+		{Opcode: BRANCH_CALL, Src: 1, Offset: -1}, // calls itself forever
+		{Opcode: BRANCH_EXIT},
+	}
+
+	vm, err := NewInterpreter(program, VMOpts{})
+	if err != nil {
+		t.Fatalf("NewInterpreter: %v", err)
+	}
+	if err := vm.Run(); err == nil {
+		t.Fatal("Run: got nil error for call depth exceeding MaxCallDepth, want an error")
+	}
+}
@@ -0,0 +1,113 @@
+package interp
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Interpreter state
+
+type State struct {
+	Memory    []byte
+	Regs      [11]int64
+	PC        int
+	Syscalls  map[uint32]Syscall
+	CallStack *Stack
+
+	// CUMax is the remaining compute-unit budget. It is decremented once
+	// per executed instruction (plus cuCallSurcharge for BRANCH_CALL);
+	// reaching zero fails the next charge with ErrComputeExceeded. Zero
+	// means metering is disabled.
+	CUMax int
+	// Trace, if non-nil, is invoked before every instruction.
+	Trace TraceSink
+}
+
+const MemorySize = 65536 // 64KB for demonstration purposes
+
+func NewState() *State {
+	s := &State{
+		Memory:    make([]byte, MemorySize),
+		Syscalls:  make(map[uint32]Syscall),
+		CallStack: NewStack(),
+	}
+	// R10 is the frame pointer: it starts at the top of frame 0, the
+	// highest StackFrameSize bytes of Memory.
+	s.Regs[10] = int64(MemorySize)
+	return s
+}
+
+// RegisterSyscall wires a helper function into the syscall table under id,
+// the value BRANCH_CALL looks up via Imm when Src == 0.
+func (s *State) RegisterSyscall(id uint32, fn Syscall) {
+	if s.Syscalls == nil {
+		s.Syscalls = make(map[uint32]Syscall)
+	}
+	s.Syscalls[id] = fn
+}
+
+func (s *State) storeWord(address int64, value int32) error {
+	if address < 0 || address+4 > int64(len(s.Memory)) {
+		return errors.New("memory access out of bounds")
+	}
+	binary.LittleEndian.PutUint32(s.Memory[address:address+4], uint32(value))
+	return nil
+}
+
+func (s *State) storeHalfWord(address int64, value int16) error {
+	if address < 0 || address+2 > int64(len(s.Memory)) {
+		return errors.New("memory access out of bounds")
+	}
+	binary.LittleEndian.PutUint16(s.Memory[address:address+2], uint16(value))
+	return nil
+}
+
+func (s *State) storeByte(address int64, value int8) error {
+	if address < 0 || address+1 > int64(len(s.Memory)) {
+		return errors.New("memory access out of bounds")
+	}
+	s.Memory[address] = byte(value)
+	return nil
+}
+
+func (s *State) storeDoubleWord(address int64, value int64) error {
+	if address < 0 || address+8 > int64(len(s.Memory)) {
+		return errors.New("memory access out of bounds")
+	}
+	binary.LittleEndian.PutUint64(s.Memory[address:address+8], uint64(value))
+	return nil
+}
+
+func (s *State) loadWord(address int64) int64 {
+	if address < 0 || address+4 > int64(len(s.Memory)) {
+		return 0
+	}
+	return int64(binary.LittleEndian.Uint32(s.Memory[address : address+4]))
+}
+
+func (s *State) loadHalfWord(address int64) int64 {
+	if address < 0 || address+2 > int64(len(s.Memory)) {
+		return 0
+	}
+	return int64(int16(binary.LittleEndian.Uint16(s.Memory[address : address+2])))
+}
+
+func (s *State) loadByte(address int64) int64 {
+	if address < 0 || address+1 > int64(len(s.Memory)) {
+		return 0
+	}
+	return int64(int8(s.Memory[address]))
+}
+
+func (s *State) loadDoubleWord(address int64) int64 {
+	if address < 0 || address+8 > int64(len(s.Memory)) {
+		return 0
+	}
+	return int64(binary.LittleEndian.Uint64(s.Memory[address : address+8]))
+}
+
+// setLow32 stores a 32-bit ALU result in dst, zero-extending it into the
+// upper 32 bits of the register as the eBPF ALU32 class requires.
+func (s *State) setLow32(dst uint8, value int32) {
+	s.Regs[dst] = int64(uint32(value))
+}
@@ -0,0 +1,31 @@
+package interp
+
+import "fmt"
+
+// Syscall is a host function that an eBPF program can invoke via
+// BRANCH_CALL with Src == 0. Args mirror R1..R5 at the call site; the
+// returned value is written into R0.
+type Syscall interface {
+	Invoke(s *State, a, b, c, d, e uint64) (uint64, error)
+}
+
+// SyscallFunc adapts a plain function to the Syscall interface.
+type SyscallFunc func(s *State, a, b, c, d, e uint64) (uint64, error)
+
+func (f SyscallFunc) Invoke(s *State, a, b, c, d, e uint64) (uint64, error) {
+	return f(s, a, b, c, d, e)
+}
+
+// Built-in syscall ids. Programs reference these as the Imm of a
+// BRANCH_CALL instruction; hosts are free to register their own ids
+// alongside or instead of these.
+const (
+	SyscallTracePrintf uint32 = 6
+)
+
+// TracePrintfSyscall is a minimal stand-in for bpf_trace_printk: it prints
+// its first argument as a %d-formatted message and returns 0.
+var TracePrintfSyscall = SyscallFunc(func(s *State, a, b, c, d, e uint64) (uint64, error) {
+	fmt.Printf("trace: %d\n", a)
+	return 0, nil
+})
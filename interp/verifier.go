@@ -0,0 +1,164 @@
+package interp
+
+import (
+	"errors"
+	"fmt"
+)
+
+// maxWriteReg is the highest register index Verify allows as a write
+// target: R10 is the frame pointer and is read-only.
+const maxWriteReg = 9
+
+// maxReadReg is the highest register index Verify allows as a read
+// source; Regs has 11 valid slots (R0-R10), but the 4-bit Dst/Src fields
+// can encode up to 15.
+const maxReadReg = 10
+
+// Verify performs a lightweight static check over program before it is
+// ever executed. Execute trusts its input completely and may panic or
+// silently corrupt state on bytecode that doesn't pass Verify — the same
+// contract real eBPF/SBF interpreters make with their verifiers.
+func Verify(program []Instruction) error {
+	// lddwSlot marks the second half of each MEM_LDDW pair: a pseudo-slot
+	// that isn't a real instruction and can't be a branch target.
+	lddwSlot := make(map[int]bool)
+
+	for i, instr := range program {
+		if lddwSlot[i] {
+			continue
+		}
+
+		if instr.Opcode == MEM_LDDW {
+			if i+1 >= len(program) {
+				return fmt.Errorf("instruction %d: LDDW has no following pseudo-instruction slot", i)
+			}
+			lddwSlot[i+1] = true
+		}
+
+		if err := verifyRegisters(i, instr); err != nil {
+			return err
+		}
+	}
+
+	for i, instr := range program {
+		if lddwSlot[i] || !isBranchOpcode(instr.Opcode) {
+			continue
+		}
+		target := i + 1 + int(instr.Offset)
+		if target < 0 || target > len(program) {
+			return fmt.Errorf("instruction %d: branch target %d is out of range", i, target)
+		}
+		if target < len(program) && lddwSlot[target] {
+			return fmt.Errorf("instruction %d: branch target %d lands inside an LDDW pair", i, target)
+		}
+	}
+
+	last := len(program) - 1
+	for last >= 0 && lddwSlot[last] {
+		last--
+	}
+	if last < 0 || !terminatesControlFlow(program[last].Opcode) {
+		return errors.New("program may fall off the end without exiting or looping back")
+	}
+
+	return nil
+}
+
+func verifyRegisters(i int, instr Instruction) error {
+	if writesDst(instr.Opcode) && instr.Dst > maxWriteReg {
+		return fmt.Errorf("instruction %d: write to out-of-range or read-only register r%d", i, instr.Dst)
+	}
+	if readsDst(instr.Opcode) && instr.Dst > maxReadReg {
+		return fmt.Errorf("instruction %d: read from out-of-range register r%d", i, instr.Dst)
+	}
+	if readsSrc(instr.Opcode) && instr.Src > maxReadReg {
+		return fmt.Errorf("instruction %d: read from out-of-range register r%d", i, instr.Src)
+	}
+	return nil
+}
+
+// writesDst reports whether instr.Dst is a write target, i.e. must not be
+// R10.
+func writesDst(op uint8) bool {
+	switch op {
+	case ALU64_ADD_IMM, ALU64_ADD_REG, ALU64_SUB_IMM, ALU64_SUB_REG,
+		ALU64_MUL_IMM, ALU64_MUL_REG, ALU64_DIV_IMM, ALU64_DIV_REG,
+		ALU64_OR_IMM, ALU64_OR_REG, ALU64_AND_IMM, ALU64_AND_REG,
+		ALU64_LSH_IMM, ALU64_LSH_REG, ALU64_RSH_IMM, ALU64_RSH_REG,
+		ALU64_NEG, ALU64_MOD_IMM, ALU64_MOD_REG, ALU64_XOR_IMM, ALU64_XOR_REG,
+		ALU64_MOV_IMM, ALU64_MOV_REG, ALU64_ARSH_IMM, ALU64_ARSH_REG,
+		ALU32_ADD_IMM, ALU32_ADD_REG, ALU32_SUB_IMM, ALU32_SUB_REG,
+		ALU32_MUL_IMM, ALU32_MUL_REG, ALU32_DIV_IMM, ALU32_DIV_REG,
+		ALU32_OR_IMM, ALU32_OR_REG, ALU32_AND_IMM, ALU32_AND_REG,
+		ALU32_LSH_IMM, ALU32_LSH_REG, ALU32_RSH_IMM, ALU32_RSH_REG,
+		ALU32_NEG, ALU32_MOD_IMM, ALU32_MOD_REG, ALU32_XOR_IMM, ALU32_XOR_REG,
+		ALU32_MOV_IMM, ALU32_MOV_REG, ALU32_ARSH_IMM, ALU32_ARSH_REG,
+		BYTESWAP_LE, BYTESWAP_BE,
+		MEM_LDDW, MEM_LDXW, MEM_LDXH, MEM_LDXB, MEM_LDXDW:
+		return true
+	default:
+		return false
+	}
+}
+
+// readsDst reports whether instr.Dst is read rather than written, e.g.
+// the base address of a store or the left-hand side of a branch. These
+// are allowed to be R10.
+func readsDst(op uint8) bool {
+	switch op {
+	case MEM_STW, MEM_STH, MEM_STB, MEM_STDW,
+		MEM_STXW, MEM_STXH, MEM_STXB, MEM_STXDW,
+		BRANCH_JEQ_IMM, BRANCH_JEQ_REG, BRANCH_JGT_IMM, BRANCH_JGT_REG,
+		BRANCH_JGE_IMM, BRANCH_JGE_REG, BRANCH_JLT_IMM, BRANCH_JLT_REG,
+		BRANCH_JLE_IMM, BRANCH_JLE_REG, BRANCH_JSET_IMM, BRANCH_JSET_REG,
+		BRANCH_JNE_IMM, BRANCH_JNE_REG, BRANCH_JSGT_IMM, BRANCH_JSGT_REG,
+		BRANCH_JSGE_IMM, BRANCH_JSGE_REG, BRANCH_JSLT_IMM, BRANCH_JSLT_REG,
+		BRANCH_JSLE_IMM, BRANCH_JSLE_REG:
+		return true
+	default:
+		return false
+	}
+}
+
+// readsSrc reports whether instr.Src is a register read. It's false for
+// the _IMM ALU/branch variants (Src is unused) and for BRANCH_CALL, where
+// Src is repurposed as the syscall-vs-intra-call flag rather than a
+// register.
+func readsSrc(op uint8) bool {
+	switch op {
+	case ALU64_ADD_REG, ALU64_SUB_REG, ALU64_MUL_REG, ALU64_DIV_REG,
+		ALU64_OR_REG, ALU64_AND_REG, ALU64_LSH_REG, ALU64_RSH_REG,
+		ALU64_MOD_REG, ALU64_XOR_REG, ALU64_MOV_REG, ALU64_ARSH_REG,
+		ALU32_ADD_REG, ALU32_SUB_REG, ALU32_MUL_REG, ALU32_DIV_REG,
+		ALU32_OR_REG, ALU32_AND_REG, ALU32_LSH_REG, ALU32_RSH_REG,
+		ALU32_MOD_REG, ALU32_XOR_REG, ALU32_MOV_REG, ALU32_ARSH_REG,
+		MEM_STXW, MEM_STXH, MEM_STXB, MEM_STXDW,
+		BRANCH_JEQ_REG, BRANCH_JGT_REG, BRANCH_JGE_REG, BRANCH_JLT_REG,
+		BRANCH_JLE_REG, BRANCH_JSET_REG, BRANCH_JNE_REG, BRANCH_JSGT_REG,
+		BRANCH_JSGE_REG, BRANCH_JSLT_REG, BRANCH_JSLE_REG:
+		return true
+	default:
+		return false
+	}
+}
+
+func isBranchOpcode(op uint8) bool {
+	switch op {
+	case BRANCH_JA, BRANCH_JEQ_IMM, BRANCH_JEQ_REG, BRANCH_JGT_IMM, BRANCH_JGT_REG,
+		BRANCH_JGE_IMM, BRANCH_JGE_REG, BRANCH_JLT_IMM, BRANCH_JLT_REG,
+		BRANCH_JLE_IMM, BRANCH_JLE_REG, BRANCH_JSET_IMM, BRANCH_JSET_REG,
+		BRANCH_JNE_IMM, BRANCH_JNE_REG, BRANCH_JSGT_IMM, BRANCH_JSGT_REG,
+		BRANCH_JSGE_IMM, BRANCH_JSGE_REG, BRANCH_JSLT_IMM, BRANCH_JSLT_REG,
+		BRANCH_JSLE_IMM, BRANCH_JSLE_REG, BRANCH_CALL:
+		return true
+	default:
+		return false
+	}
+}
+
+// terminatesControlFlow reports whether op can end a program without
+// falling through to the next slot: it either exits for good or always
+// jumps elsewhere.
+func terminatesControlFlow(op uint8) bool {
+	return op == BRANCH_EXIT || op == BRANCH_JA
+}
@@ -0,0 +1,65 @@
+package interp
+
+import "testing"
+
+// TestVerifyRejectsOutOfRangeRegisters checks that Verify catches an
+// out-of-range Dst (> maxReadReg) and a write to R10 (the frame
+// pointer) for every opcode class that writes Dst, not just ALU64 —
+// ALU32 and BYTESWAP were added in chunk0-4 without updating writesDst.
+func TestVerifyRejectsOutOfRangeRegisters(t *testing.T) {
+	tests := []struct {
+		name    string
+		program []Instruction
+	}{
+		{
+			name: "alu32 dst out of range",
+			program: []Instruction{
+				{Opcode: ALU32_ADD_IMM, Dst: 15, Imm: 1},
+				{Opcode: BRANCH_EXIT},
+			},
+		},
+		{
+			name: "alu32 write to r10",
+			program: []Instruction{
+				{Opcode: ALU32_MOV_IMM, Dst: 10, Imm: 999},
+				{Opcode: BRANCH_EXIT},
+			},
+		},
+		{
+			name: "alu32 reg src out of range",
+			program: []Instruction{
+				{Opcode: ALU32_ADD_REG, Dst: 0, Src: 15},
+				{Opcode: BRANCH_EXIT},
+			},
+		},
+		{
+			name: "byteswap le write to r10",
+			program: []Instruction{
+				{Opcode: BYTESWAP_LE, Dst: 10, Imm: 64},
+				{Opcode: BRANCH_EXIT},
+			},
+		},
+		{
+			name: "byteswap be write to r10",
+			program: []Instruction{
+				{Opcode: BYTESWAP_BE, Dst: 10, Imm: 64},
+				{Opcode: BRANCH_EXIT},
+			},
+		},
+		{
+			name: "byteswap dst out of range",
+			program: []Instruction{
+				{Opcode: BYTESWAP_LE, Dst: 15, Imm: 64},
+				{Opcode: BRANCH_EXIT},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Verify(tt.program); err == nil {
+				t.Fatal("Verify: got nil error, want a register-range error")
+			}
+		})
+	}
+}
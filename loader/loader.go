@@ -0,0 +1,145 @@
+// Package loader builds an interp.Program from an eBPF-flavored ELF
+// object file, the format produced by clang/LLVM's bpf target.
+package loader
+
+import (
+	"debug/elf"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/mryalamanchi/eBPF-interpreter/interp"
+)
+
+// rBPF6432 is the BPF ELF relocation type for a call to another function
+// within the program (R_BPF_64_32). debug/elf has no BPF-specific
+// relocation constants, so it is declared here.
+const rBPF6432 = 10
+
+// instructionSize is the width in bytes of one decoded eBPF instruction
+// slot, matching interp.Decode.
+const instructionSize = 8
+
+// LoadELF parses an eBPF ELF object (EM_BPF, little-endian) and returns
+// the Program it describes: .text decoded as instructions, .rodata and
+// .data concatenated as the program's initial read-only memory, and
+// BPF-to-BPF call relocations (R_BPF_64_32) resolved so each CALL's Imm
+// and Offset point at the callee's instruction index.
+func LoadELF(r io.ReaderAt) (*interp.Program, error) {
+	f, err := elf.NewFile(r)
+	if err != nil {
+		return nil, fmt.Errorf("loader: %w", err)
+	}
+	if f.Machine != elf.EM_BPF {
+		return nil, fmt.Errorf("loader: unsupported ELF machine %s, want EM_BPF", f.Machine)
+	}
+	if f.ByteOrder != binary.LittleEndian {
+		return nil, fmt.Errorf("loader: unsupported byte order %s, want little-endian", f.ByteOrder)
+	}
+
+	text := f.Section(".text")
+	if text == nil {
+		return nil, fmt.Errorf("loader: no .text section")
+	}
+	textBytes, err := text.Data()
+	if err != nil {
+		return nil, fmt.Errorf("loader: reading .text: %w", err)
+	}
+	program := interp.Decode(textBytes)
+
+	var ro []byte
+	for _, name := range []string{".rodata", ".data"} {
+		sec := f.Section(name)
+		if sec == nil {
+			continue
+		}
+		data, err := sec.Data()
+		if err != nil {
+			return nil, fmt.Errorf("loader: reading %s: %w", name, err)
+		}
+		ro = append(ro, data...)
+	}
+
+	funcs, err := resolveCallRelocations(f, program)
+	if err != nil {
+		return nil, err
+	}
+
+	return &interp.Program{
+		Text:       program,
+		RO:         ro,
+		Entrypoint: 0,
+		Funcs:      funcs,
+	}, nil
+}
+
+// resolveCallRelocations rewrites the Imm and Offset of every BRANCH_CALL
+// in program that carries an R_BPF_64_32 relocation against a .text
+// symbol, so it points at the callee's intra-program instruction index.
+// It returns a map from the relocation's symbol index to that index.
+func resolveCallRelocations(f *elf.File, program []interp.Instruction) (map[uint32]int, error) {
+	funcs := make(map[uint32]int)
+
+	relSec := f.Section(".rel.text")
+	rela := false
+	if relSec == nil {
+		relSec = f.Section(".rela.text")
+		rela = true
+	}
+	if relSec == nil {
+		return funcs, nil
+	}
+
+	data, err := relSec.Data()
+	if err != nil {
+		return nil, fmt.Errorf("loader: reading %s: %w", relSec.Name, err)
+	}
+
+	symbols, err := f.Symbols()
+	if err != nil {
+		return nil, fmt.Errorf("loader: reading symbols: %w", err)
+	}
+
+	entrySize := 16
+	if rela {
+		entrySize = 24
+	}
+
+	for off := 0; off+entrySize <= len(data); off += entrySize {
+		relOff := binary.LittleEndian.Uint64(data[off:])
+		info := binary.LittleEndian.Uint64(data[off+8:])
+		symIdx := uint32(info >> 32)
+		relType := uint32(info)
+
+		if relType != rBPF6432 {
+			continue
+		}
+		if symIdx < 1 || int(symIdx) > len(symbols) {
+			return nil, fmt.Errorf("loader: relocation references out-of-range symbol %d", symIdx)
+		}
+		target := int(symbols[symIdx-1].Value) / instructionSize
+		if target < 0 || target >= len(program) {
+			return nil, fmt.Errorf("loader: relocation targets out-of-range instruction %d", target)
+		}
+
+		callIdx := int(relOff) / instructionSize
+		if callIdx < 0 || callIdx >= len(program) {
+			return nil, fmt.Errorf("loader: relocation at offset %d is outside .text", relOff)
+		}
+		if program[callIdx].Opcode != interp.BRANCH_CALL {
+			continue
+		}
+
+		callOffset := target - (callIdx + 1)
+		if callOffset < math.MinInt16 || callOffset > math.MaxInt16 {
+			return nil, fmt.Errorf("loader: call at instruction %d to %d is out of Offset range", callIdx, target)
+		}
+
+		program[callIdx].Imm = int32(target)
+		program[callIdx].Offset = int16(callOffset)
+		funcs[symIdx] = target
+	}
+
+	return funcs, nil
+}
@@ -0,0 +1,172 @@
+package loader
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/mryalamanchi/eBPF-interpreter/interp"
+)
+
+// buildObject hand-assembles a minimal ELF64 LE EM_BPF object with a
+// .text section holding two instructions ("call foo; exit") followed by
+// foo's own two instructions ("r0 = 42; exit"), a symtab entry for foo,
+// and a REL relocation of type relType against foo for the call at text
+// offset 0. There's no BPF assembler available in this sandbox, so this
+// stands in for `clang -target bpf` output.
+func buildObject(t *testing.T, relType uint32, symIdx uint32) []byte {
+	t.Helper()
+
+	insn := func(op, dstSrc byte, offset int16, imm int32) []byte {
+		b := make([]byte, 8)
+		b[0] = op
+		b[1] = dstSrc
+		binary.LittleEndian.PutUint16(b[2:], uint16(offset))
+		binary.LittleEndian.PutUint32(b[4:], uint32(imm))
+		return b
+	}
+
+	var text []byte
+	text = append(text, insn(interp.BRANCH_CALL, 1<<4, 0, 0)...) // call foo (src=1: intra-program)
+	text = append(text, insn(interp.BRANCH_EXIT, 0, 0, 0)...)
+	text = append(text, insn(interp.ALU64_MOV_IMM, 0, 0, 42)...) // foo: r0 = 42
+	text = append(text, insn(interp.BRANCH_EXIT, 0, 0, 0)...)
+
+	strtab := []byte{0}
+	fooNameOff := uint32(len(strtab))
+	strtab = append(strtab, []byte("foo\x00")...)
+
+	symtab := make([]byte, 24*2) // null symbol + foo
+	binary.LittleEndian.PutUint32(symtab[24:], fooNameOff)
+	symtab[24+4] = 0x12                              // STB_GLOBAL<<4 | STT_FUNC
+	binary.LittleEndian.PutUint16(symtab[24+6:], 1)  // shndx: .text
+	binary.LittleEndian.PutUint64(symtab[24+8:], 16) // value: foo's byte offset in .text
+	binary.LittleEndian.PutUint64(symtab[24+16:], 16)
+
+	rel := make([]byte, 16) // Rel64: r_offset=0 (the call instruction), r_info(symIdx, relType)
+	binary.LittleEndian.PutUint64(rel[8:], uint64(symIdx)<<32|uint64(relType))
+
+	shstrtab := []byte{0}
+	addName := func(n string) uint32 {
+		off := uint32(len(shstrtab))
+		shstrtab = append(shstrtab, append([]byte(n), 0)...)
+		return off
+	}
+	textName := addName(".text")
+	relName := addName(".rel.text")
+	symtabName := addName(".symtab")
+	strtabName := addName(".strtab")
+	shstrtabName := addName(".shstrtab")
+
+	const ehsize, shentsize = 64, 64
+	align := func(o uint64) uint64 {
+		for o%8 != 0 {
+			o++
+		}
+		return o
+	}
+
+	off := uint64(ehsize)
+	textOff := off
+	off = align(off + uint64(len(text)))
+	relOff := off
+	off = align(off + uint64(len(rel)))
+	symtabOff := off
+	off = align(off + uint64(len(symtab)))
+	strtabOff := off
+	off = align(off + uint64(len(strtab)))
+	shstrtabOff := off
+	off = align(off + uint64(len(shstrtab)))
+	shoff := off
+
+	buf := make([]byte, shoff+uint64(shentsize*6))
+	copy(buf[0:4], []byte{0x7f, 'E', 'L', 'F'})
+	buf[4], buf[5], buf[6] = 2, 1, 1             // ELFCLASS64, ELFDATA2LSB, EV_CURRENT
+	binary.LittleEndian.PutUint16(buf[16:], 1)   // e_type = ET_REL
+	binary.LittleEndian.PutUint16(buf[18:], 247) // e_machine = EM_BPF
+	binary.LittleEndian.PutUint32(buf[20:], 1)   // e_version
+	binary.LittleEndian.PutUint16(buf[52:], ehsize)
+	binary.LittleEndian.PutUint64(buf[40:], shoff)
+	binary.LittleEndian.PutUint16(buf[58:], shentsize)
+	binary.LittleEndian.PutUint16(buf[60:], 6) // e_shnum
+	binary.LittleEndian.PutUint16(buf[62:], 5) // e_shstrndx
+
+	copy(buf[textOff:], text)
+	copy(buf[relOff:], rel)
+	copy(buf[symtabOff:], symtab)
+	copy(buf[strtabOff:], strtab)
+	copy(buf[shstrtabOff:], shstrtab)
+
+	writeShdr := func(idx int, name, typ uint32, offset, size uint64, link, info uint32, entsize uint64) {
+		base := shoff + uint64(idx)*shentsize
+		binary.LittleEndian.PutUint32(buf[base:], name)
+		binary.LittleEndian.PutUint32(buf[base+4:], typ)
+		binary.LittleEndian.PutUint64(buf[base+24:], offset)
+		binary.LittleEndian.PutUint64(buf[base+32:], size)
+		binary.LittleEndian.PutUint32(buf[base+40:], link)
+		binary.LittleEndian.PutUint32(buf[base+44:], info)
+		binary.LittleEndian.PutUint64(buf[base+56:], entsize)
+	}
+
+	const shtNull, shtProgbits, shtSymtab, shtStrtab, shtRel = 0, 1, 2, 3, 9
+
+	writeShdr(0, 0, shtNull, 0, 0, 0, 0, 0)
+	writeShdr(1, textName, shtProgbits, textOff, uint64(len(text)), 0, 0, 0)
+	writeShdr(2, relName, shtRel, relOff, uint64(len(rel)), 3, 1, 16) // link: symtab, info: target section (.text)
+	writeShdr(3, symtabName, shtSymtab, symtabOff, uint64(len(symtab)), 4, 1, 24)
+	writeShdr(4, strtabName, shtStrtab, strtabOff, uint64(len(strtab)), 0, 0, 0)
+	writeShdr(5, shstrtabName, shtStrtab, shstrtabOff, uint64(len(shstrtab)), 0, 0, 0)
+
+	return buf
+}
+
+func TestLoadELFResolvesCallRelocation(t *testing.T) {
+	obj := buildObject(t, rBPF6432, 1)
+
+	p, err := LoadELF(bytes.NewReader(obj))
+	if err != nil {
+		t.Fatalf("LoadELF: %v", err)
+	}
+	if len(p.Text) != 4 {
+		t.Fatalf("got %d instructions, want 4", len(p.Text))
+	}
+
+	call := p.Text[0]
+	if call.Imm != 2 {
+		t.Errorf("call Imm = %d, want 2 (foo's instruction index)", call.Imm)
+	}
+	if call.Offset != 1 {
+		t.Errorf("call Offset = %d, want 1 (pc-relative to foo)", call.Offset)
+	}
+	if got, want := p.Funcs[1], 2; got != want {
+		t.Errorf("Funcs[1] = %d, want %d", got, want)
+	}
+
+	vm, err := interp.NewInterpreterFromProgram(p, interp.VMOpts{})
+	if err != nil {
+		t.Fatalf("NewInterpreterFromProgram: %v", err)
+	}
+	if err := vm.Run(); err != nil && err.Error() != "exit" {
+		t.Fatalf("Run: %v", err)
+	}
+	if vm.State.Regs[0] != 42 {
+		t.Errorf("R0 = %d, want 42", vm.State.Regs[0])
+	}
+}
+
+func TestLoadELFRejectsOutOfRangeSymbol(t *testing.T) {
+	obj := buildObject(t, rBPF6432, 99)
+
+	if _, err := LoadELF(bytes.NewReader(obj)); err == nil {
+		t.Fatal("LoadELF: got nil error for an out-of-range relocation symbol, want an error")
+	}
+}
+
+func TestLoadELFRejectsNonBPFMachine(t *testing.T) {
+	obj := buildObject(t, rBPF6432, 1)
+	binary.LittleEndian.PutUint16(obj[18:], 62) // e_machine = EM_X86_64
+
+	if _, err := LoadELF(bytes.NewReader(obj)); err == nil {
+		t.Fatal("LoadELF: got nil error for a non-BPF machine, want an error")
+	}
+}